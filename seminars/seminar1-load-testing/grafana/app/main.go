@@ -1,16 +1,23 @@
 package main
 
 import (
+    "context"
+    "flag"
     "fmt"
     "log"
     "net/http"
+    "os"
+    "os/signal"
+    "regexp"
+    "strings"
+    "syscall"
+    "time"
 
     "github.com/prometheus/client_golang/prometheus"
     "github.com/prometheus/client_golang/prometheus/promhttp"
-    "github.com/shirou/gopsutil/v3/cpu"
-    "github.com/shirou/gopsutil/v3/disk"
-    "github.com/shirou/gopsutil/v3/host"
-    "github.com/shirou/gopsutil/v3/mem"
+
+    "github.com/gvsem/itmo-ddia-course/seminars/seminar1-load-testing/grafana/app/pkg/hwexporter"
+    "github.com/gvsem/itmo-ddia-course/seminars/seminar1-load-testing/grafana/app/pkg/promapi"
 )
 
 // Example counter
@@ -22,8 +29,19 @@ var requestsTotal = prometheus.NewCounterVec(
     []string{"path"},
 )
 
+// httpDuration tracks RED latency for the app handler, labeled by path and
+// status code.
+var httpDuration = prometheus.NewHistogramVec(
+    prometheus.HistogramOpts{
+        Name:    "app_http_request_duration_seconds",
+        Help:    "Latency of app HTTP requests in seconds",
+        Buckets: prometheus.ExponentialBuckets(0.0001, 2, 24),
+    },
+    []string{"path", "code"},
+)
+
 func init() {
-    prometheus.MustRegister(requestsTotal)
+    prometheus.MustRegister(requestsTotal, httpDuration)
 }
 
 func handler(w http.ResponseWriter, r *http.Request) {
@@ -31,9 +49,55 @@ func handler(w http.ResponseWriter, r *http.Request) {
     fmt.Fprintln(w, "OK")
 }
 
+var (
+    collectorCPU    = flag.Bool("collector.cpu", true, "Enable the cpu collector")
+    collectorMemory = flag.Bool("collector.memory", true, "Enable the memory collector")
+    collectorDisk   = flag.Bool("collector.disk", true, "Enable the disk collector")
+    collectorUptime = flag.Bool("collector.uptime", true, "Enable the uptime collector")
+    collectorNetdev = flag.Bool("collector.netdev", true, "Enable the network device collector")
+    sampleInterval  = flag.Duration("sample-interval", 5*time.Second, "Interval at which hardware collectors refresh their metrics in the background")
+
+    diskMountpointInclude = flag.String("collector.disk.mountpoint-include", "", "Regex a mountpoint must match to be reported (default: match all)")
+    diskMountpointExclude = flag.String("collector.disk.mountpoint-exclude", `^/(dev|proc|sys|run)(/|$)`, "Regex excluding mountpoints from being reported")
+
+    netdevInclude = flag.String("collector.netdev.include", "", "Regex an interface must match to be reported (default: match all)")
+    netdevExclude = flag.String("collector.netdev.exclude", "^(lo|veth|docker|br-)", "Regex excluding interfaces from being reported")
+
+    collectorSystemd = flag.Bool("collector.systemd", false, "Enable the systemd unit collector (requires D-Bus access)")
+    systemdUnits     = flag.String("collector.systemd.units", "", "Comma-separated systemd unit names or glob patterns to report on, e.g. \"sshd.service,myapp-*.service\"")
+    controlToken     = flag.String("control.token", "", "Bearer token required to call the systemd control endpoint (endpoint disabled if empty)")
+
+    pushGatewayURL = flag.String("push.gateway-url", "", "Pushgateway URL to push the hardware registry to (disabled if empty)")
+    pushJob        = flag.String("push.job", "hwexporter", "Job name to push metrics under")
+    pushInstance   = flag.String("push.instance", "", "Value of the \"instance\" and \"host\" grouping labels used when pushing (defaults to the hostname)")
+    pushInterval   = flag.Duration("push.interval", 15*time.Second, "Interval at which metrics are pushed to the Pushgateway")
+
+    promAPIURL = flag.String("promapi.url", "", "Prometheus server URL used to self-test that this exporter is being scraped (disabled if empty)")
+)
+
+// mustCompileOptional compiles pattern, returning nil if it is empty.
+// flagName is used in the fatal error message when pattern doesn't compile.
+func mustCompileOptional(flagName, pattern string) *regexp.Regexp {
+    if pattern == "" {
+        return nil
+    }
+    re, err := regexp.Compile(pattern)
+    if err != nil {
+        log.Fatalf("--%s: invalid regex %q: %v", flagName, pattern, err)
+    }
+    return re
+}
+
 func main() {
-    // Application HTTP handler
-    http.HandleFunc("/", handler)
+    flag.Parse()
+
+    // Application HTTP handler, instrumented with per-status latency. Every
+    // request it serves is recorded under the path label "/".
+    instrumentedHandler := promhttp.InstrumentHandlerDuration(
+        httpDuration.MustCurryWith(prometheus.Labels{"path": "/"}),
+        http.HandlerFunc(handler),
+    )
+    http.Handle("/", instrumentedHandler)
 
     // Run main application server on :8081
     go func() {
@@ -43,91 +107,129 @@ func main() {
         }
     }()
 
-    // Create a separate Prometheus registry for hardware metrics
-    hwRegistry := prometheus.NewRegistry()
-
-    // CPU percent (overall)
-    cpuGauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
-        Name: "host_cpu_percent",
-        Help: "Total CPU usage percent",
-    }, func() float64 {
-        pct, err := cpu.Percent(0, false)
-        if err != nil || len(pct) == 0 {
-            return 0
+    // Build the hardware metrics registry from the enabled collectors.
+    hwReg := hwexporter.NewRegistry()
+
+    if *collectorCPU {
+        if err := hwReg.Enable(hwexporter.NewCPUCollector(*sampleInterval)); err != nil {
+            log.Fatalf("enabling cpu collector: %v", err)
         }
-        return pct[0]
-    })
-    hwRegistry.MustRegister(cpuGauge)
-
-    // Memory
-    memTotal := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
-        Name: "host_memory_total_bytes",
-        Help: "Total system memory in bytes",
-    }, func() float64 {
-        v, err := mem.VirtualMemory()
-        if err != nil {
-            return 0
+    }
+    if *collectorMemory {
+        if err := hwReg.Enable(hwexporter.NewMemoryCollector()); err != nil {
+            log.Fatalf("enabling memory collector: %v", err)
         }
-        return float64(v.Total)
-    })
-    memUsed := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
-        Name: "host_memory_used_bytes",
-        Help: "Used system memory in bytes",
-    }, func() float64 {
-        v, err := mem.VirtualMemory()
-        if err != nil {
-            return 0
+    }
+    if *collectorDisk {
+        include := mustCompileOptional("collector.disk.mountpoint-include", *diskMountpointInclude)
+        exclude := mustCompileOptional("collector.disk.mountpoint-exclude", *diskMountpointExclude)
+        if err := hwReg.Enable(hwexporter.NewDiskCollector(include, exclude)); err != nil {
+            log.Fatalf("enabling disk collector: %v", err)
         }
-        return float64(v.Used)
-    })
-    hwRegistry.MustRegister(memTotal, memUsed)
-
-    // Disk usage for root
-    diskTotal := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
-        Name: "host_disk_total_bytes",
-        Help: "Total disk bytes for root",
-    }, func() float64 {
-        d, err := disk.Usage("/")
-        if err != nil {
-            return 0
+    }
+    if *collectorUptime {
+        if err := hwReg.Enable(hwexporter.NewUptimeCollector()); err != nil {
+            log.Fatalf("enabling uptime collector: %v", err)
         }
-        return float64(d.Total)
-    })
-    diskUsed := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
-        Name: "host_disk_used_bytes",
-        Help: "Used disk bytes for root",
-    }, func() float64 {
-        d, err := disk.Usage("/")
-        if err != nil {
-            return 0
+    }
+    if *collectorNetdev {
+        include := mustCompileOptional("collector.netdev.include", *netdevInclude)
+        exclude := mustCompileOptional("collector.netdev.exclude", *netdevExclude)
+        if err := hwReg.Enable(hwexporter.NewNetCollector(include, exclude)); err != nil {
+            log.Fatalf("enabling netdev collector: %v", err)
+        }
+    }
+    var systemdUnitList []string
+    if *collectorSystemd {
+        systemdUnitList = strings.FieldsFunc(*systemdUnits, func(r rune) bool { return r == ',' })
+        if len(systemdUnitList) == 0 {
+            log.Fatalf("--collector.systemd requires --collector.systemd.units")
+        }
+        if err := hwReg.Enable(hwexporter.NewSystemdCollector(systemdUnitList)); err != nil {
+            log.Fatalf("enabling systemd collector: %v", err)
         }
-        return float64(d.Used)
-    })
-    hwRegistry.MustRegister(diskTotal, diskUsed)
-
-    // Host uptime
-    uptime := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
-        Name: "host_uptime_seconds",
-        Help: "System uptime in seconds",
-    }, func() float64 {
-        u, err := host.Uptime()
+    }
+
+    // Refresh hardware metrics in the background so scrapes never block on
+    // gopsutil and CPU percent is measured over a real sampling window.
+    hwReg.StartSampling(*sampleInterval, nil)
+
+    // Optionally self-test against the Prometheus server scraping us: query
+    // back the newest host_cpu_percent sample and report freshness/lag. The
+    // query spans every host_cpu_percent series on that server; samples from
+    // other exporters scraped by the same server are not told apart.
+    var runSelfTest func()
+    if *promAPIURL != "" {
+        promClient, err := promapi.New(*promAPIURL)
         if err != nil {
-            return 0
+            log.Fatalf("promapi: %v", err)
+        }
+
+        scrapeLag := prometheus.NewGauge(prometheus.GaugeOpts{
+            Name: "exporter_scrape_lag_seconds",
+            Help: "Seconds since Prometheus last observed any host_cpu_percent sample",
+        })
+        if err := hwReg.Gatherer().Register(scrapeLag); err != nil {
+            log.Fatalf("registering exporter_scrape_lag_seconds: %v", err)
         }
-        return float64(u)
-    })
-    hwRegistry.MustRegister(uptime)
+
+        runSelfTest = func() {
+            ts, err := promClient.LatestSampleTime(context.Background(), "host_cpu_percent")
+            if err != nil {
+                log.Printf("selftest: %v", err)
+                return
+            }
+            scrapeLag.Set(time.Since(ts).Seconds())
+        }
+        go runSelfTest()
+    }
 
     // Start hardware metrics server on :8082
     go func() {
         mux := http.NewServeMux()
-        mux.Handle("/metrics", promhttp.HandlerFor(hwRegistry, promhttp.HandlerOpts{}))
+        mux.Handle("/metrics", promhttp.HandlerFor(hwReg.Gatherer(), promhttp.HandlerOpts{}))
+        if *collectorSystemd && *controlToken != "" {
+            mux.Handle("/control/systemd", hwexporter.ControlHandler(*controlToken, systemdUnitList))
+        }
+        if runSelfTest != nil {
+            mux.HandleFunc("/selftest", func(w http.ResponseWriter, r *http.Request) {
+                runSelfTest()
+                fmt.Fprintln(w, "selftest complete, see exporter_scrape_lag_seconds")
+            })
+        }
         fmt.Println("Hardware metrics server listening on :8082")
         if err := http.ListenAndServe(":8082", mux); err != nil {
             log.Fatalf("hardware metrics server failed: %v", err)
         }
     }()
 
+    // Optionally push the hardware registry to a Pushgateway, for hosts
+    // that cannot be scraped directly.
+    if *pushGatewayURL != "" {
+        instance := *pushInstance
+        if instance == "" {
+            if h, err := os.Hostname(); err == nil {
+                instance = h
+            }
+        }
+
+        pusher := hwexporter.NewPusher(hwReg, *pushGatewayURL, *pushJob, map[string]string{
+            "instance": instance,
+            "host":     instance,
+        })
+        pusher.Start(*pushInterval, nil)
+
+        sigCh := make(chan os.Signal, 1)
+        signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+        go func() {
+            <-sigCh
+            if err := pusher.DeleteOnShutdown(); err != nil {
+                log.Printf("pushgateway: delete on shutdown failed: %v", err)
+            }
+            os.Exit(0)
+        }()
+    }
+
     // Block forever
     select {}
 }
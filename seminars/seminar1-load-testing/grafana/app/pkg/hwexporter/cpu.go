@@ -0,0 +1,67 @@
+package hwexporter
+
+import (
+    "strconv"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/shirou/gopsutil/v3/cpu"
+)
+
+// CPUCollector exposes overall and per-core CPU usage percent, sampled over
+// a real measurement window rather than read instantaneously on scrape.
+type CPUCollector struct {
+    window time.Duration
+
+    overall prometheus.Gauge
+    perCPU  *prometheus.GaugeVec
+}
+
+// NewCPUCollector returns a Collector for CPU usage. window is the
+// measurement window passed to cpu.Percent when Sample is called; it
+// should match (or be shorter than) the Registry's sampling interval.
+func NewCPUCollector(window time.Duration) *CPUCollector {
+    return &CPUCollector{
+        window: window,
+        overall: prometheus.NewGauge(prometheus.GaugeOpts{
+            Name: "host_cpu_percent",
+            Help: "Total CPU usage percent, averaged across cores",
+        }),
+        perCPU: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "host_cpu_percent_percpu",
+            Help: "Per-core CPU usage percent",
+        }, []string{"cpu"}),
+    }
+}
+
+func (c *CPUCollector) Name() string { return "cpu" }
+
+func (c *CPUCollector) Describe(ch chan<- *prometheus.Desc) {
+    c.overall.Describe(ch)
+    c.perCPU.Describe(ch)
+}
+
+func (c *CPUCollector) Collect(ch chan<- prometheus.Metric) {
+    c.overall.Collect(ch)
+    c.perCPU.Collect(ch)
+}
+
+// Sample blocks for c.window measuring real per-core CPU usage, then
+// publishes it along with the average across cores.
+func (c *CPUCollector) Sample() error {
+    pct, err := cpu.Percent(c.window, true)
+    if err != nil {
+        return err
+    }
+    if len(pct) == 0 {
+        return nil
+    }
+
+    var sum float64
+    for i, p := range pct {
+        c.perCPU.WithLabelValues(strconv.Itoa(i)).Set(p)
+        sum += p
+    }
+    c.overall.Set(sum / float64(len(pct)))
+    return nil
+}
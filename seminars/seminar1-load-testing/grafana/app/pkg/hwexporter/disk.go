@@ -0,0 +1,155 @@
+package hwexporter
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/shirou/gopsutil/v3/disk"
+)
+
+// DiskCollector exposes per-mountpoint disk usage and per-device I/O
+// counters. Mountpoints are filtered by include/exclude regexes to avoid
+// cardinality blowup from tmpfs/overlay mounts; a nil regex matches
+// everything (include) or nothing (exclude).
+type DiskCollector struct {
+    include *regexp.Regexp
+    exclude *regexp.Regexp
+
+    total *prometheus.GaugeVec
+    used  *prometheus.GaugeVec
+
+    readBytes  *prometheus.CounterVec
+    writeBytes *prometheus.CounterVec
+    ioTime     *prometheus.CounterVec
+
+    lastIO          map[string]disk.IOCountersStat
+    lastMountpoints map[string]struct{}
+}
+
+// NewDiskCollector returns a Collector reporting usage for mountpoints
+// matching include and not matching exclude, and I/O counters for every
+// device reported by disk.IOCounters; the regexes do not filter devices.
+func NewDiskCollector(include, exclude *regexp.Regexp) *DiskCollector {
+    return &DiskCollector{
+        include: include,
+        exclude: exclude,
+        total: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "host_disk_total_bytes",
+            Help: "Total disk bytes for the mountpoint",
+        }, []string{"device", "mountpoint", "fstype"}),
+        used: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "host_disk_used_bytes",
+            Help: "Used disk bytes for the mountpoint",
+        }, []string{"device", "mountpoint", "fstype"}),
+        readBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "host_disk_read_bytes_total",
+            Help: "Cumulative bytes read from the device",
+        }, []string{"device"}),
+        writeBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "host_disk_write_bytes_total",
+            Help: "Cumulative bytes written to the device",
+        }, []string{"device"}),
+        ioTime: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "host_disk_io_time_seconds_total",
+            Help: "Cumulative time spent on I/O by the device",
+        }, []string{"device"}),
+        lastIO:          make(map[string]disk.IOCountersStat),
+        lastMountpoints: make(map[string]struct{}),
+    }
+}
+
+func (c *DiskCollector) Name() string { return "disk" }
+
+func (c *DiskCollector) Describe(ch chan<- *prometheus.Desc) {
+    c.total.Describe(ch)
+    c.used.Describe(ch)
+    c.readBytes.Describe(ch)
+    c.writeBytes.Describe(ch)
+    c.ioTime.Describe(ch)
+}
+
+func (c *DiskCollector) Collect(ch chan<- prometheus.Metric) {
+    c.total.Collect(ch)
+    c.used.Collect(ch)
+    c.readBytes.Collect(ch)
+    c.writeBytes.Collect(ch)
+    c.ioTime.Collect(ch)
+}
+
+func (c *DiskCollector) matches(mountpoint string) bool {
+    if c.exclude != nil && c.exclude.MatchString(mountpoint) {
+        return false
+    }
+    if c.include != nil && !c.include.MatchString(mountpoint) {
+        return false
+    }
+    return true
+}
+
+func (c *DiskCollector) Sample() error {
+    partitions, err := disk.Partitions(false)
+    if err != nil {
+        return fmt.Errorf("hwexporter: listing partitions: %w", err)
+    }
+
+    seenMountpoints := make(map[string]struct{}, len(partitions))
+    for _, p := range partitions {
+        if !c.matches(p.Mountpoint) {
+            continue
+        }
+        usage, err := disk.Usage(p.Mountpoint)
+        if err != nil {
+            continue
+        }
+        device := deviceName(p.Device)
+        c.total.WithLabelValues(device, p.Mountpoint, p.Fstype).Set(float64(usage.Total))
+        c.used.WithLabelValues(device, p.Mountpoint, p.Fstype).Set(float64(usage.Used))
+        seenMountpoints[p.Mountpoint] = struct{}{}
+    }
+    for mountpoint := range c.lastMountpoints {
+        if _, ok := seenMountpoints[mountpoint]; !ok {
+            c.total.DeletePartialMatch(prometheus.Labels{"mountpoint": mountpoint})
+            c.used.DeletePartialMatch(prometheus.Labels{"mountpoint": mountpoint})
+        }
+    }
+    c.lastMountpoints = seenMountpoints
+
+    counters, err := disk.IOCounters()
+    if err != nil {
+        return fmt.Errorf("hwexporter: reading disk io counters: %w", err)
+    }
+    for device := range c.lastIO {
+        if _, ok := counters[device]; !ok {
+            delete(c.lastIO, device)
+            c.readBytes.DeleteLabelValues(device)
+            c.writeBytes.DeleteLabelValues(device)
+            c.ioTime.DeleteLabelValues(device)
+        }
+    }
+    for device, cur := range counters {
+        prev, seen := c.lastIO[device]
+        c.lastIO[device] = cur
+        if !seen {
+            continue
+        }
+        if cur.ReadBytes > prev.ReadBytes {
+            c.readBytes.WithLabelValues(device).Add(float64(cur.ReadBytes - prev.ReadBytes))
+        }
+        if cur.WriteBytes > prev.WriteBytes {
+            c.writeBytes.WithLabelValues(device).Add(float64(cur.WriteBytes - prev.WriteBytes))
+        }
+        if cur.IoTime > prev.IoTime {
+            c.ioTime.WithLabelValues(device).Add(float64(cur.IoTime-prev.IoTime) / 1000)
+        }
+    }
+    return nil
+}
+
+// deviceName strips a "/dev/" prefix so partition device paths (as
+// returned by disk.Partitions) line up with the bare device names used as
+// keys by disk.IOCounters.
+func deviceName(path string) string {
+    return strings.TrimPrefix(path, "/dev/")
+}
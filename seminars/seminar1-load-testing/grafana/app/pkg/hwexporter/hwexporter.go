@@ -0,0 +1,61 @@
+// Package hwexporter provides a small, composable library of Prometheus
+// collectors for host hardware metrics (CPU, memory, disk, uptime, ...).
+//
+// Binaries wire up the collectors they want into a Registry and expose it
+// over /metrics, the same way node_exporter composes its collectors.
+package hwexporter
+
+import (
+    "fmt"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a named prometheus.Collector that can be toggled on or off
+// by a binary wiring up a Registry.
+type Collector interface {
+    prometheus.Collector
+
+    // Name identifies the collector, e.g. "cpu", "disk". Used for flag
+    // names (--collector.<name>) and duplicate-registration errors.
+    Name() string
+}
+
+// Registry composes a set of enabled Collectors behind a single
+// *prometheus.Registry suitable for promhttp.HandlerFor.
+type Registry struct {
+    reg        *prometheus.Registry
+    collectors map[string]Collector
+    samplers   []Sampler
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+    return &Registry{
+        reg:        prometheus.NewRegistry(),
+        collectors: make(map[string]Collector),
+    }
+}
+
+// Enable registers c with the underlying registry. It returns an error if a
+// collector with the same Name is already enabled. If c also implements
+// Sampler, it is picked up by a later call to StartSampling.
+func (r *Registry) Enable(c Collector) error {
+    if _, ok := r.collectors[c.Name()]; ok {
+        return fmt.Errorf("hwexporter: collector %q already enabled", c.Name())
+    }
+    if err := r.reg.Register(c); err != nil {
+        return fmt.Errorf("hwexporter: registering collector %q: %w", c.Name(), err)
+    }
+    r.collectors[c.Name()] = c
+    if s, ok := c.(Sampler); ok {
+        r.samplers = append(r.samplers, s)
+    }
+    return nil
+}
+
+// Gatherer exposes the underlying prometheus.Registry for use with
+// promhttp.HandlerFor.
+func (r *Registry) Gatherer() *prometheus.Registry {
+    return r.reg
+}
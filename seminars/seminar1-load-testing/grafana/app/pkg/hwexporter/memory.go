@@ -0,0 +1,48 @@
+package hwexporter
+
+import (
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/shirou/gopsutil/v3/mem"
+)
+
+// MemoryCollector exposes total and used system memory.
+type MemoryCollector struct {
+    total prometheus.Gauge
+    used  prometheus.Gauge
+}
+
+// NewMemoryCollector returns a Collector for system memory usage.
+func NewMemoryCollector() *MemoryCollector {
+    return &MemoryCollector{
+        total: prometheus.NewGauge(prometheus.GaugeOpts{
+            Name: "host_memory_total_bytes",
+            Help: "Total system memory in bytes",
+        }),
+        used: prometheus.NewGauge(prometheus.GaugeOpts{
+            Name: "host_memory_used_bytes",
+            Help: "Used system memory in bytes",
+        }),
+    }
+}
+
+func (c *MemoryCollector) Name() string { return "memory" }
+
+func (c *MemoryCollector) Describe(ch chan<- *prometheus.Desc) {
+    c.total.Describe(ch)
+    c.used.Describe(ch)
+}
+
+func (c *MemoryCollector) Collect(ch chan<- prometheus.Metric) {
+    c.total.Collect(ch)
+    c.used.Collect(ch)
+}
+
+func (c *MemoryCollector) Sample() error {
+    v, err := mem.VirtualMemory()
+    if err != nil {
+        return err
+    }
+    c.total.Set(float64(v.Total))
+    c.used.Set(float64(v.Used))
+    return nil
+}
@@ -0,0 +1,100 @@
+package hwexporter
+
+import (
+    "fmt"
+    "regexp"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/shirou/gopsutil/v3/net"
+)
+
+// NetCollector exposes per-interface network I/O counters. Interfaces are
+// filtered by include/exclude regexes to avoid cardinality blowup from
+// virtual interfaces; a nil regex matches everything (include) or nothing
+// (exclude).
+type NetCollector struct {
+    include *regexp.Regexp
+    exclude *regexp.Regexp
+
+    rxBytes *prometheus.CounterVec
+    txBytes *prometheus.CounterVec
+
+    lastCounters map[string]net.IOCountersStat
+}
+
+// NewNetCollector returns a Collector reporting I/O counters for interfaces
+// matching include and not matching exclude.
+func NewNetCollector(include, exclude *regexp.Regexp) *NetCollector {
+    return &NetCollector{
+        include: include,
+        exclude: exclude,
+        rxBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "host_network_receive_bytes_total",
+            Help: "Cumulative bytes received on the interface",
+        }, []string{"interface"}),
+        txBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "host_network_transmit_bytes_total",
+            Help: "Cumulative bytes transmitted on the interface",
+        }, []string{"interface"}),
+        lastCounters: make(map[string]net.IOCountersStat),
+    }
+}
+
+func (c *NetCollector) Name() string { return "netdev" }
+
+func (c *NetCollector) Describe(ch chan<- *prometheus.Desc) {
+    c.rxBytes.Describe(ch)
+    c.txBytes.Describe(ch)
+}
+
+func (c *NetCollector) Collect(ch chan<- prometheus.Metric) {
+    c.rxBytes.Collect(ch)
+    c.txBytes.Collect(ch)
+}
+
+func (c *NetCollector) matches(iface string) bool {
+    if c.exclude != nil && c.exclude.MatchString(iface) {
+        return false
+    }
+    if c.include != nil && !c.include.MatchString(iface) {
+        return false
+    }
+    return true
+}
+
+func (c *NetCollector) Sample() error {
+    counters, err := net.IOCounters(true)
+    if err != nil {
+        return fmt.Errorf("hwexporter: reading network io counters: %w", err)
+    }
+
+    seen := make(map[string]struct{}, len(counters))
+    for _, cur := range counters {
+        if !c.matches(cur.Name) {
+            continue
+        }
+        seen[cur.Name] = struct{}{}
+
+        prev, hadPrev := c.lastCounters[cur.Name]
+        c.lastCounters[cur.Name] = cur
+        if !hadPrev {
+            continue
+        }
+        if cur.BytesRecv > prev.BytesRecv {
+            c.rxBytes.WithLabelValues(cur.Name).Add(float64(cur.BytesRecv - prev.BytesRecv))
+        }
+        if cur.BytesSent > prev.BytesSent {
+            c.txBytes.WithLabelValues(cur.Name).Add(float64(cur.BytesSent - prev.BytesSent))
+        }
+    }
+
+    for iface := range c.lastCounters {
+        if _, ok := seen[iface]; ok {
+            continue
+        }
+        delete(c.lastCounters, iface)
+        c.rxBytes.DeleteLabelValues(iface)
+        c.txBytes.DeleteLabelValues(iface)
+    }
+    return nil
+}
@@ -0,0 +1,57 @@
+package hwexporter
+
+import (
+    "log"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Pusher periodically pushes a Registry's metrics to a Prometheus
+// Pushgateway. This is for hosts that cannot be scraped directly, e.g.
+// behind NAT, or for short-lived / one-shot jobs.
+type Pusher struct {
+    pusher *push.Pusher
+}
+
+// NewPusher returns a Pusher that pushes r's metrics to gatewayURL under the
+// given job, grouped by groupingLabels (typically "instance" and "host").
+func NewPusher(r *Registry, gatewayURL, job string, groupingLabels map[string]string) *Pusher {
+    p := push.New(gatewayURL, job).Gatherer(r.Gatherer())
+    for name, value := range groupingLabels {
+        p = p.Grouping(name, value)
+    }
+    return &Pusher{pusher: p}
+}
+
+// Start pushes once immediately, then again every interval, until stop is
+// closed. Push errors are logged and do not stop the loop.
+func (p *Pusher) Start(interval time.Duration, stop <-chan struct{}) {
+    push := func() {
+        if err := p.pusher.Push(); err != nil {
+            log.Printf("hwexporter: push to gateway failed: %v", err)
+        }
+    }
+
+    go func() {
+        push()
+
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ticker.C:
+                push()
+            case <-stop:
+                return
+            }
+        }
+    }()
+}
+
+// DeleteOnShutdown deletes this job's metric group from the gateway so
+// stale metrics don't linger after the process exits. Call it from a
+// signal handler before the process terminates.
+func (p *Pusher) DeleteOnShutdown() error {
+    return p.pusher.Delete()
+}
@@ -0,0 +1,55 @@
+package hwexporter
+
+import (
+    "log"
+    "time"
+)
+
+// Sampler is implemented by collectors that refresh their metrics from the
+// host on a background schedule instead of on every scrape. This keeps
+// scrape latency bounded and lets collectors like CPUCollector use a real
+// measurement window instead of an instantaneous zero-window sample.
+type Sampler interface {
+    // Sample refreshes the collector's metrics. Called periodically by
+    // StartSampling.
+    Sample() error
+}
+
+// StartSampling runs Sample on every enabled Sampler once immediately, then
+// again every interval, until stop is closed. Each Sampler runs on its own
+// goroutine, so a slow or blocking Sample (e.g. CPUCollector, which blocks
+// for a full measurement window) never delays the others. The time spent
+// inside Sample counts against the interval, so collectors refresh at the
+// configured cadence as long as Sample returns within the interval; a Sample
+// that takes longer is followed immediately by the next one.
+func (r *Registry) StartSampling(interval time.Duration, stop <-chan struct{}) {
+    for _, s := range r.samplers {
+        go runSampler(s, interval, stop)
+    }
+}
+
+func runSampler(s Sampler, interval time.Duration, stop <-chan struct{}) {
+    sample := func() time.Duration {
+        start := time.Now()
+        if err := s.Sample(); err != nil {
+            log.Printf("hwexporter: sampling error: %v", err)
+        }
+        return time.Since(start)
+    }
+
+    for {
+        elapsed := sample()
+
+        wait := interval - elapsed
+        if wait < 0 {
+            wait = 0
+        }
+        timer := time.NewTimer(wait)
+        select {
+        case <-timer.C:
+        case <-stop:
+            timer.Stop()
+            return
+        }
+    }
+}
@@ -0,0 +1,159 @@
+package hwexporter
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "math"
+    "strings"
+    "sync"
+
+    "github.com/coreos/go-systemd/v22/dbus"
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+// SystemdCollector exports per-unit CPU and memory usage, read over D-Bus,
+// for a configured set of systemd units. Entries in units may be glob
+// patterns, e.g. "myapp-*.service", as accepted by systemctl.
+type SystemdCollector struct {
+    units []string
+
+    mu      sync.Mutex
+    lastCPU map[string]uint64 // unit name -> last seen CPUUsageNSec
+
+    cpuUsage   *prometheus.CounterVec
+    memCurrent *prometheus.GaugeVec
+    tasks      *prometheus.GaugeVec
+    cpuQuota   *prometheus.GaugeVec
+    memMax     *prometheus.GaugeVec
+    memHigh    *prometheus.GaugeVec
+}
+
+// NewSystemdCollector returns a Collector reporting CPU, memory, and task
+// usage for the given systemd units.
+func NewSystemdCollector(units []string) *SystemdCollector {
+    return &SystemdCollector{
+        units:   units,
+        lastCPU: make(map[string]uint64),
+        cpuUsage: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "systemd_unit_cpu_usage_seconds_total",
+            Help: "Cumulative CPU time consumed by the unit, in seconds",
+        }, []string{"unit"}),
+        memCurrent: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "systemd_unit_memory_bytes",
+            Help: "Current memory usage of the unit, in bytes",
+        }, []string{"unit"}),
+        tasks: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "systemd_unit_tasks_current",
+            Help: "Current number of tasks (processes/threads) in the unit",
+        }, []string{"unit"}),
+        cpuQuota: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "systemd_unit_cpu_quota_per_sec_useconds",
+            Help: "Configured CPU quota per second, in microseconds",
+        }, []string{"unit"}),
+        memMax: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "systemd_unit_memory_max_bytes",
+            Help: "Configured hard memory limit, in bytes",
+        }, []string{"unit"}),
+        memHigh: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name: "systemd_unit_memory_high_bytes",
+            Help: "Configured soft (throttling) memory limit, in bytes",
+        }, []string{"unit"}),
+    }
+}
+
+func (c *SystemdCollector) Name() string { return "systemd" }
+
+func (c *SystemdCollector) Describe(ch chan<- *prometheus.Desc) {
+    c.cpuUsage.Describe(ch)
+    c.memCurrent.Describe(ch)
+    c.tasks.Describe(ch)
+    c.cpuQuota.Describe(ch)
+    c.memMax.Describe(ch)
+    c.memHigh.Describe(ch)
+}
+
+func (c *SystemdCollector) Collect(ch chan<- prometheus.Metric) {
+    c.cpuUsage.Collect(ch)
+    c.memCurrent.Collect(ch)
+    c.tasks.Collect(ch)
+    c.cpuQuota.Collect(ch)
+    c.memMax.Collect(ch)
+    c.memHigh.Collect(ch)
+}
+
+// Sample connects to systemd over D-Bus and refreshes metrics for every
+// unit matching c.units.
+func (c *SystemdCollector) Sample() error {
+    ctx := context.Background()
+    conn, err := dbus.NewSystemConnectionContext(ctx)
+    if err != nil {
+        return fmt.Errorf("hwexporter: connecting to systemd: %w", err)
+    }
+    defer conn.Close()
+
+    units, err := conn.ListUnitsByPatternsContext(ctx, nil, c.units)
+    if err != nil {
+        return fmt.Errorf("hwexporter: listing units: %w", err)
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    for _, u := range units {
+        props, err := conn.GetUnitTypePropertiesContext(ctx, u.Name, unitType(u.Name))
+        if err != nil {
+            log.Printf("hwexporter: reading properties for unit %q: %v", u.Name, err)
+            continue
+        }
+
+        if v, ok := propUint64(props, "CPUUsageNSec"); ok {
+            prev, seen := c.lastCPU[u.Name]
+            if seen && v > prev {
+                c.cpuUsage.WithLabelValues(u.Name).Add(float64(v-prev) / 1e9)
+            }
+            c.lastCPU[u.Name] = v
+        }
+        if v, ok := propUint64(props, "MemoryCurrent"); ok {
+            c.memCurrent.WithLabelValues(u.Name).Set(float64(v))
+        }
+        if v, ok := propUint64(props, "TasksCurrent"); ok {
+            c.tasks.WithLabelValues(u.Name).Set(float64(v))
+        }
+        if v, ok := propUint64(props, "CPUQuotaPerSecUSec"); ok {
+            c.cpuQuota.WithLabelValues(u.Name).Set(float64(v))
+        }
+        if v, ok := propUint64(props, "MemoryMax"); ok {
+            c.memMax.WithLabelValues(u.Name).Set(float64(v))
+        }
+        if v, ok := propUint64(props, "MemoryHigh"); ok {
+            c.memHigh.WithLabelValues(u.Name).Set(float64(v))
+        }
+    }
+    return nil
+}
+
+// unitType derives the D-Bus unit interface suffix (e.g. "Service",
+// "Slice") from a unit name's file extension.
+func unitType(unit string) string {
+    idx := strings.LastIndex(unit, ".")
+    if idx < 0 || idx == len(unit)-1 {
+        return "Service"
+    }
+    ext := unit[idx+1:]
+    return strings.ToUpper(ext[:1]) + ext[1:]
+}
+
+// propUint64 reads a uint64 D-Bus property, treating systemd's "unset"
+// sentinel (math.MaxUint64) as absent.
+func propUint64(props map[string]interface{}, name string) (uint64, bool) {
+    raw, ok := props[name]
+    if !ok {
+        return 0, false
+    }
+    v, ok := raw.(uint64)
+    if !ok || v == math.MaxUint64 {
+        return 0, false
+    }
+    return v, true
+}
@@ -0,0 +1,117 @@
+package hwexporter
+
+import (
+    "crypto/subtle"
+    "encoding/json"
+    "math"
+    "net/http"
+    "path"
+
+    systemddbus "github.com/coreos/go-systemd/v22/dbus"
+    godbus "github.com/godbus/dbus/v5"
+)
+
+// UnitProperty is a single systemd unit property to set, as accepted by
+// SetUnitPropertiesContext, e.g. {"name": "MemoryMax", "value": 536870912}.
+type UnitProperty struct {
+    Name  string      `json:"name"`
+    Value interface{} `json:"value"`
+}
+
+// SetUnitPropertiesRequest is the JSON body accepted by ControlHandler.
+type SetUnitPropertiesRequest struct {
+    Unit       string         `json:"unit"`
+    Runtime    bool           `json:"runtime"`
+    Properties []UnitProperty `json:"properties"`
+}
+
+// ControlHandler returns an http.Handler that lets an authenticated caller
+// change resource-control properties (e.g. MemoryMax, CPUQuotaPerSecUSec)
+// on a live systemd unit via SetUnitPropertiesContext, so this exporter can
+// double as a resource-control agent for the units it observes. Requests
+// are rejected unless their Unit matches one of units (the same unit names
+// or glob patterns the SystemdCollector was configured with), so the
+// endpoint cannot be used to control units outside those being monitored.
+//
+// Requests must carry "Authorization: Bearer <token>" matching token.
+func ControlHandler(token string, units []string) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+            http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+            return
+        }
+        if !authorized(r, token) {
+            http.Error(w, "unauthorized", http.StatusUnauthorized)
+            return
+        }
+
+        var req SetUnitPropertiesRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+        if req.Unit == "" || len(req.Properties) == 0 {
+            http.Error(w, "unit and properties are required", http.StatusBadRequest)
+            return
+        }
+        if !unitAllowed(units, req.Unit) {
+            http.Error(w, "unit is not in the monitored unit list", http.StatusForbidden)
+            return
+        }
+
+        ctx := r.Context()
+        conn, err := systemddbus.NewSystemConnectionContext(ctx)
+        if err != nil {
+            http.Error(w, "connecting to systemd: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        defer conn.Close()
+
+        props := make([]systemddbus.Property, 0, len(req.Properties))
+        for _, p := range req.Properties {
+            value := p.Value
+            // JSON numbers decode as float64, but systemd's numeric
+            // resource-control properties (MemoryMax, ...) are uint64.
+            if f, ok := value.(float64); ok && f >= 0 && f == math.Trunc(f) {
+                value = uint64(f)
+            }
+            props = append(props, systemddbus.Property{
+                Name:  p.Name,
+                Value: godbus.MakeVariant(value),
+            })
+        }
+
+        if err := conn.SetUnitPropertiesContext(ctx, req.Unit, req.Runtime, props...); err != nil {
+            http.Error(w, "setting unit properties: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+
+        w.WriteHeader(http.StatusNoContent)
+    })
+}
+
+// authorized reports whether r carries the expected bearer token, compared
+// in constant time to avoid leaking the token through response-timing.
+func authorized(r *http.Request, token string) bool {
+    if token == "" {
+        return false
+    }
+    got := r.Header.Get("Authorization")
+    want := "Bearer " + token
+    return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// unitAllowed reports whether unit matches one of units, which may contain
+// exact names or glob patterns, mirroring the matching ListUnitsByPatternsContext
+// applies when the SystemdCollector builds its metrics.
+func unitAllowed(units []string, unit string) bool {
+    for _, pattern := range units {
+        if pattern == unit {
+            return true
+        }
+        if ok, err := path.Match(pattern, unit); err == nil && ok {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,32 @@
+package hwexporter
+
+import (
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/shirou/gopsutil/v3/host"
+)
+
+// UptimeCollector exposes system uptime.
+type UptimeCollector struct {
+    prometheus.Gauge
+}
+
+// NewUptimeCollector returns a Collector for system uptime.
+func NewUptimeCollector() *UptimeCollector {
+    return &UptimeCollector{
+        Gauge: prometheus.NewGauge(prometheus.GaugeOpts{
+            Name: "host_uptime_seconds",
+            Help: "System uptime in seconds",
+        }),
+    }
+}
+
+func (c *UptimeCollector) Name() string { return "uptime" }
+
+func (c *UptimeCollector) Sample() error {
+    u, err := host.Uptime()
+    if err != nil {
+        return err
+    }
+    c.Set(float64(u))
+    return nil
+}
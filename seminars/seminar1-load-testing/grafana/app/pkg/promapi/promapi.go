@@ -0,0 +1,61 @@
+// Package promapi is a small typed wrapper around the Prometheus HTTP API
+// (github.com/prometheus/client_golang/api/prometheus/v1), used by this
+// exporter to query back the Prometheus server that scrapes it.
+package promapi
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/prometheus/client_golang/api"
+    v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+    "github.com/prometheus/common/model"
+)
+
+// Client queries a Prometheus server's HTTP API.
+type Client struct {
+    api v1.API
+}
+
+// New returns a Client talking to the Prometheus server at addr, e.g.
+// "http://localhost:9090".
+func New(addr string) (*Client, error) {
+    c, err := api.NewClient(api.Config{Address: addr})
+    if err != nil {
+        return nil, fmt.Errorf("promapi: creating client: %w", err)
+    }
+    return &Client{api: v1.NewAPI(c)}, nil
+}
+
+// LatestSampleTime returns the timestamp of metric's most recent sample,
+// across all of its label series. It queries timestamp(metric) rather than
+// metric itself: an instant query on metric alone returns the query's own
+// evaluation time as long as the series is inside the staleness window,
+// not the time it was actually last scraped, which would make freshness
+// checks read as "fresh" right up until the series goes stale and the
+// query starts erroring.
+func (c *Client) LatestSampleTime(ctx context.Context, metric string) (time.Time, error) {
+    query := fmt.Sprintf("timestamp(%s)", metric)
+    val, warnings, err := c.api.Query(ctx, query, time.Now())
+    if err != nil {
+        return time.Time{}, fmt.Errorf("promapi: querying %q: %w", query, err)
+    }
+    for _, w := range warnings {
+        log.Printf("promapi: warning querying %q: %s", query, w)
+    }
+
+    vec, ok := val.(model.Vector)
+    if !ok || len(vec) == 0 {
+        return time.Time{}, fmt.Errorf("promapi: no samples found for %q", metric)
+    }
+
+    latest := vec[0].Value
+    for _, s := range vec[1:] {
+        if s.Value > latest {
+            latest = s.Value
+        }
+    }
+    return time.Unix(0, int64(float64(latest)*float64(time.Second))), nil
+}